@@ -0,0 +1,385 @@
+package gpx
+
+import (
+	"math"
+	"time"
+)
+
+// earthRadiusMeters is the WGS84 mean radius used for haversine distances.
+const earthRadiusMeters = 6371008.8
+
+// DefaultMovingSpeedThreshold is a reasonable speed, in m/s, below which a
+// gap between two points should be counted as stopped rather than moving
+// (about 1 km/h) - pass it to MovingTime/StoppedTime/MovingAveragePace
+// when the caller has no better threshold of their own.
+const DefaultMovingSpeedThreshold = 0.28
+
+// haversineMeters returns the great-circle distance between two WGS84
+// coordinates, in meters.
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	lat1r, lat2r := lat1*rad, lat2*rad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1r)*math.Cos(lat2r)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadiusMeters * c
+}
+
+func distance2D(a, b WayPoint) float64 {
+	return haversineMeters(float64(a.Latitude), float64(a.Longitude), float64(b.Latitude), float64(b.Longitude))
+}
+
+func distance3D(a, b WayPoint) float64 {
+	horizontal := distance2D(a, b)
+	vertical := b.Elevation - a.Elevation
+	return math.Sqrt(horizontal*horizontal + vertical*vertical)
+}
+
+func length2D(points []WayPoint) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += distance2D(points[i-1], points[i])
+	}
+	return total
+}
+
+func length3D(points []WayPoint) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += distance3D(points[i-1], points[i])
+	}
+	return total
+}
+
+func duration(points []WayPoint) time.Duration {
+	if len(points) == 0 {
+		return 0
+	}
+	first, last := points[0].Timestamp, points[len(points)-1].Timestamp
+	if first.IsZero() || last.IsZero() {
+		return 0
+	}
+	return last.Sub(first.Time)
+}
+
+// speedBetween returns the average 2D speed, in m/s, between points i and j.
+func speedBetween(points []WayPoint, i, j int) float64 {
+	if i < 0 || j < 0 || i >= len(points) || j >= len(points) || i == j {
+		return 0
+	}
+	a, b := points[i], points[j]
+	if a.Timestamp.IsZero() || b.Timestamp.IsZero() {
+		return 0
+	}
+	elapsed := b.Timestamp.Sub(a.Timestamp.Time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return distance2D(a, b) / elapsed
+}
+
+// speedAt returns the instantaneous speed, in m/s, at point i, averaged
+// from the segments on either side of it.
+func speedAt(points []WayPoint, i int) float64 {
+	switch {
+	case len(points) < 2:
+		return 0
+	case i <= 0:
+		return speedBetween(points, 0, 1)
+	case i >= len(points)-1:
+		return speedBetween(points, len(points)-2, len(points)-1)
+	default:
+		return (speedBetween(points, i-1, i) + speedBetween(points, i, i+1)) / 2
+	}
+}
+
+func maxSpeed(points []WayPoint) float64 {
+	var max float64
+	for i := 1; i < len(points); i++ {
+		if s := speedBetween(points, i-1, i); s > max {
+			max = s
+		}
+	}
+	return max
+}
+
+// movingTime splits the elapsed time between consecutive points into time
+// spent moving (2D speed >= threshold) and time spent stopped.
+func movingTime(points []WayPoint, threshold float64) (moving, stopped time.Duration) {
+	for i := 1; i < len(points); i++ {
+		if points[i].Timestamp.IsZero() || points[i-1].Timestamp.IsZero() {
+			continue
+		}
+		elapsed := points[i].Timestamp.Sub(points[i-1].Timestamp.Time)
+		if elapsed <= 0 {
+			continue
+		}
+		if speedBetween(points, i-1, i) >= threshold {
+			moving += elapsed
+		} else {
+			stopped += elapsed
+		}
+	}
+	return moving, stopped
+}
+
+// movingAveragePace returns the average pace, as time per kilometer, over
+// the portions of the track where speed was at or above threshold.
+func movingAveragePace(points []WayPoint, threshold float64) time.Duration {
+	moving, _ := movingTime(points, threshold)
+	var movingDistance float64
+	for i := 1; i < len(points); i++ {
+		if speedBetween(points, i-1, i) >= threshold {
+			movingDistance += distance2D(points[i-1], points[i])
+		}
+	}
+	if movingDistance == 0 {
+		return 0
+	}
+	secondsPerKM := moving.Seconds() / (movingDistance / 1000)
+	return time.Duration(secondsPerKM * float64(time.Second))
+}
+
+// uphillDownhill returns cumulative elevation gain and loss, in meters.
+// The elevation series is smoothed first with a centered moving-average
+// window (see smoothElevation) to reject GPS/barometric noise, since
+// differencing the raw series wildly overstates both figures.
+func uphillDownhill(points []WayPoint, window int) (uphill, downhill float64) {
+	if len(points) < 2 {
+		return 0, 0
+	}
+	smoothed := smoothElevation(points, window)
+	for i := 1; i < len(smoothed); i++ {
+		switch diff := smoothed[i] - smoothed[i-1]; {
+		case diff > 0:
+			uphill += diff
+		case diff < 0:
+			downhill += -diff
+		}
+	}
+	return uphill, downhill
+}
+
+// smoothElevation returns a copy of each point's elevation run through a
+// centered moving-average filter of the given window size, to reject the
+// noise that makes raw elevation deltas overstate gain/loss. A window <= 1
+// is a no-op.
+func smoothElevation(points []WayPoint, window int) []float64 {
+	smoothed := make([]float64, len(points))
+	if window <= 1 {
+		for i, p := range points {
+			smoothed[i] = p.Elevation
+		}
+		return smoothed
+	}
+
+	half := window / 2
+	for i := range points {
+		lo, hi := i-half, i+half
+		if lo < 0 {
+			lo = 0
+		}
+		if hi >= len(points) {
+			hi = len(points) - 1
+		}
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			sum += points[j].Elevation
+		}
+		smoothed[i] = sum / float64(hi-lo+1)
+	}
+	return smoothed
+}
+
+func computeBounds(points []WayPoint) Bounds {
+	if len(points) == 0 {
+		return Bounds{}
+	}
+	b := Bounds{
+		MinLat: float64(points[0].Latitude), MaxLat: float64(points[0].Latitude),
+		MinLon: float64(points[0].Longitude), MaxLon: float64(points[0].Longitude),
+	}
+	for _, p := range points[1:] {
+		b.MinLat = math.Min(b.MinLat, float64(p.Latitude))
+		b.MaxLat = math.Max(b.MaxLat, float64(p.Latitude))
+		b.MinLon = math.Min(b.MinLon, float64(p.Longitude))
+		b.MaxLon = math.Max(b.MaxLon, float64(p.Longitude))
+	}
+	return b
+}
+
+// points flattens every trackpoint in s into a single slice.
+func (s *TrackSegment) points() []WayPoint {
+	return s.TrackPoint
+}
+
+// points flattens every trackpoint across all of t's segments, in order.
+func (t *Track) points() []WayPoint {
+	var all []WayPoint
+	for _, seg := range t.TrackSegments {
+		all = append(all, seg.TrackPoint...)
+	}
+	return all
+}
+
+// Length2D returns the total 2D (horizontal) length of s, in meters.
+func (s *TrackSegment) Length2D() float64 { return length2D(s.points()) }
+
+// Length3D returns the total 3D length of s (accounting for elevation change), in meters.
+func (s *TrackSegment) Length3D() float64 { return length3D(s.points()) }
+
+// Duration returns the elapsed time between s's first and last trackpoint.
+func (s *TrackSegment) Duration() time.Duration { return duration(s.points()) }
+
+// MovingTime returns the time spent at or above thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (s *TrackSegment) MovingTime(thresholdMps float64) time.Duration {
+	moving, _ := movingTime(s.points(), thresholdMps)
+	return moving
+}
+
+// StoppedTime returns the time spent below thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (s *TrackSegment) StoppedTime(thresholdMps float64) time.Duration {
+	_, stopped := movingTime(s.points(), thresholdMps)
+	return stopped
+}
+
+// UphillDownhill returns cumulative elevation gain and loss, in meters,
+// after smoothing the elevation series over window points.
+func (s *TrackSegment) UphillDownhill(window int) (uphill, downhill float64) {
+	return uphillDownhill(s.points(), window)
+}
+
+// MovingAveragePace returns the average pace, as time per kilometer, over
+// the portions of s at or above thresholdMps (in m/s).
+func (s *TrackSegment) MovingAveragePace(thresholdMps float64) time.Duration {
+	return movingAveragePace(s.points(), thresholdMps)
+}
+
+// MaxSpeed returns the fastest 2D speed, in m/s, between consecutive points in s.
+func (s *TrackSegment) MaxSpeed() float64 { return maxSpeed(s.points()) }
+
+// SpeedAt returns the instantaneous 2D speed, in m/s, at point i.
+func (s *TrackSegment) SpeedAt(i int) float64 { return speedAt(s.points(), i) }
+
+// SpeedBetween returns the average 2D speed, in m/s, between points i and j.
+func (s *TrackSegment) SpeedBetween(i, j int) float64 { return speedBetween(s.points(), i, j) }
+
+// ComputeBounds returns the lat/lon bounding box of s's trackpoints.
+func (s *TrackSegment) ComputeBounds() Bounds { return computeBounds(s.points()) }
+
+// Length2D returns the total 2D length of t across all of its segments, in meters.
+func (t *Track) Length2D() float64 { return length2D(t.points()) }
+
+// Length3D returns the total 3D length of t across all of its segments, in meters.
+func (t *Track) Length3D() float64 { return length3D(t.points()) }
+
+// Duration returns the elapsed time between t's first and last trackpoint,
+// across all segments.
+func (t *Track) Duration() time.Duration { return duration(t.points()) }
+
+// MovingTime returns the time t spent at or above thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (t *Track) MovingTime(thresholdMps float64) time.Duration {
+	moving, _ := movingTime(t.points(), thresholdMps)
+	return moving
+}
+
+// StoppedTime returns the time t spent below thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (t *Track) StoppedTime(thresholdMps float64) time.Duration {
+	_, stopped := movingTime(t.points(), thresholdMps)
+	return stopped
+}
+
+// UphillDownhill returns cumulative elevation gain and loss across t, in
+// meters, after smoothing the elevation series over window points.
+func (t *Track) UphillDownhill(window int) (uphill, downhill float64) {
+	return uphillDownhill(t.points(), window)
+}
+
+// MovingAveragePace returns t's average pace, as time per kilometer, over
+// the portions of t at or above thresholdMps (in m/s).
+func (t *Track) MovingAveragePace(thresholdMps float64) time.Duration {
+	return movingAveragePace(t.points(), thresholdMps)
+}
+
+// MaxSpeed returns the fastest 2D speed, in m/s, anywhere in t.
+func (t *Track) MaxSpeed() float64 { return maxSpeed(t.points()) }
+
+// SpeedAt returns the instantaneous 2D speed, in m/s, at point i across t's flattened points.
+func (t *Track) SpeedAt(i int) float64 { return speedAt(t.points(), i) }
+
+// SpeedBetween returns the average 2D speed, in m/s, between points i and j across t's flattened points.
+func (t *Track) SpeedBetween(i, j int) float64 { return speedBetween(t.points(), i, j) }
+
+// ComputeBounds returns the lat/lon bounding box of every point in t.
+func (t *Track) ComputeBounds() Bounds { return computeBounds(t.points()) }
+
+// Length2D returns the total 2D length of r's route points, in meters.
+func (r *Route) Length2D() float64 { return length2D(r.RoutePoints) }
+
+// Length3D returns the total 3D length of r's route points, in meters.
+func (r *Route) Length3D() float64 { return length3D(r.RoutePoints) }
+
+// Duration returns the elapsed time between r's first and last route point.
+func (r *Route) Duration() time.Duration { return duration(r.RoutePoints) }
+
+// MaxSpeed returns the fastest 2D speed, in m/s, between consecutive route points.
+func (r *Route) MaxSpeed() float64 { return maxSpeed(r.RoutePoints) }
+
+// SpeedAt returns the instantaneous 2D speed, in m/s, at route point i.
+func (r *Route) SpeedAt(i int) float64 { return speedAt(r.RoutePoints, i) }
+
+// SpeedBetween returns the average 2D speed, in m/s, between route points i and j.
+func (r *Route) SpeedBetween(i, j int) float64 { return speedBetween(r.RoutePoints, i, j) }
+
+// ComputeBounds returns the lat/lon bounding box of r's route points.
+func (r *Route) ComputeBounds() Bounds { return computeBounds(r.RoutePoints) }
+
+// Length2D returns the total 2D length of g's track, in meters.
+func (g *GPX) Length2D() float64 { return g.Tracks.Length2D() }
+
+// Length3D returns the total 3D length of g's track, in meters.
+func (g *GPX) Length3D() float64 { return g.Tracks.Length3D() }
+
+// Duration returns the elapsed time between g's track's first and last trackpoint.
+func (g *GPX) Duration() time.Duration { return g.Tracks.Duration() }
+
+// MovingTime returns the time g's track spent at or above thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (g *GPX) MovingTime(thresholdMps float64) time.Duration {
+	return g.Tracks.MovingTime(thresholdMps)
+}
+
+// StoppedTime returns the time g's track spent below thresholdMps (in m/s).
+// Pass DefaultMovingSpeedThreshold for a reasonable walking-pace default.
+func (g *GPX) StoppedTime(thresholdMps float64) time.Duration {
+	return g.Tracks.StoppedTime(thresholdMps)
+}
+
+// UphillDownhill returns cumulative elevation gain and loss across g's
+// track, in meters, after smoothing the elevation series over window points.
+func (g *GPX) UphillDownhill(window int) (uphill, downhill float64) {
+	return g.Tracks.UphillDownhill(window)
+}
+
+// MovingAveragePace returns g's track's average pace, as time per
+// kilometer, over the portions at or above thresholdMps (in m/s).
+func (g *GPX) MovingAveragePace(thresholdMps float64) time.Duration {
+	return g.Tracks.MovingAveragePace(thresholdMps)
+}
+
+// MaxSpeed returns the fastest 2D speed, in m/s, anywhere in g's track.
+func (g *GPX) MaxSpeed() float64 { return g.Tracks.MaxSpeed() }
+
+// ComputeBounds computes the bounding box of g's track and stores it in
+// g.Metadata.Bounds, also returning it.
+func (g *GPX) ComputeBounds() Bounds {
+	b := g.Tracks.ComputeBounds()
+	g.Metadata.Bounds = b
+	return b
+}
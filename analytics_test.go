@@ -0,0 +1,52 @@
+package gpx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestHaversineMetersKnownDistance checks haversineMeters against a known
+// reference: one degree of longitude at the equator is ~111.2km.
+func TestHaversineMetersKnownDistance(t *testing.T) {
+	got := haversineMeters(0, 0, 0, 1)
+	want := 111195.08 // 2*pi*earthRadiusMeters/360
+	if math.Abs(got-want) > 1 {
+		t.Errorf("haversineMeters(0,0,0,1) = %v, want ~%v", got, want)
+	}
+}
+
+func wp(lat, lon float64, t time.Time) WayPoint {
+	return WayPoint{Latitude: Latitude(lat), Longitude: Longitude(lon), Timestamp: Timestamp{Time: t}}
+}
+
+// TestMovingTimeSkipsZeroTimestamps reproduces the case where Parse has
+// left a point's Timestamp zero (e.g. a malformed <time>, per time_test.go)
+// and checks that movingTime doesn't treat the gap against year 1 as an
+// enormous elapsed duration.
+func TestMovingTimeSkipsZeroTimestamps(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []WayPoint{
+		wp(0, 0, time.Time{}), // zero Timestamp, as if its <time> failed to parse
+		wp(0, 0.001, base.Add(time.Minute)),
+		wp(0, 0.002, base.Add(2*time.Minute)),
+	}
+
+	moving, stopped := movingTime(points, DefaultMovingSpeedThreshold)
+	total := moving + stopped
+	if total > time.Hour {
+		t.Fatalf("movingTime computed an absurd total duration from a zero timestamp: %v", total)
+	}
+}
+
+// TestSpeedBetweenZeroTimestamp checks speedBetween returns 0, rather than
+// a bogus value, when either endpoint's Timestamp is zero.
+func TestSpeedBetweenZeroTimestamp(t *testing.T) {
+	points := []WayPoint{
+		wp(0, 0, time.Time{}),
+		wp(0, 0.001, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)),
+	}
+	if got := speedBetween(points, 0, 1); got != 0 {
+		t.Errorf("speedBetween with a zero timestamp = %v, want 0", got)
+	}
+}
@@ -0,0 +1,174 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// TrackPoint is a trackpoint yielded by the streaming decoder. It's the
+// same shape as WayPoint, which also represents waypoints and route
+// points; the alias just makes the streaming API read naturally.
+type TrackPoint = WayPoint
+
+// Event identifies what NextEvent just decoded, so callers can rebuild
+// track/segment/route structure from a stream of points without the whole
+// document ever being buffered in memory.
+type Event int
+
+// Event values returned by (*Decoder).NextEvent.
+const (
+	EventEOF Event = iota
+	EventTrackStart
+	EventTrackEnd
+	EventSegmentStart
+	EventSegmentEnd
+	EventRouteStart
+	EventRouteEnd
+	EventTrackPoint
+	EventWaypoint
+	EventRoutePoint
+)
+
+// Decoder reads a GPX document one xml.Token at a time, materializing a
+// single WayPoint at a time rather than the whole document, so a 100MB
+// multi-day Garmin export can be processed without holding it all in RAM.
+// Parse and ParseFile remain the simpler, buffered entry points for
+// documents small enough not to need this.
+//
+// Decoder always decodes <trkpt>/<wpt>/<rtept> elements as the GPX 1.1
+// WayPoint shape, unlike Parse it does not sniff the document's version
+// first. Streaming a GPX 1.0 document through it directly therefore
+// silently drops that format's course/speed fields (WayPoint.Course and
+// WayPoint.Speed stay nil) rather than converting them the way
+// Convert10to11 does. Use Parse/ParseFile for GPX 1.0 input.
+type Decoder struct {
+	xd *xml.Decoder
+}
+
+// NewDecoder returns a Decoder that reads from r. See the Decoder doc
+// comment for its GPX 1.0 course/speed limitation.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{xd: xml.NewDecoder(r)}
+}
+
+// NextEvent advances the decoder to the next element of interest -
+// a track/segment/route boundary or a fully-decoded point - and reports
+// which. On EventTrackPoint, EventWaypoint or EventRoutePoint, point holds
+// the decoded value; it's nil otherwise. NextEvent returns EventEOF, nil,
+// io.EOF once the document is exhausted.
+func (d *Decoder) NextEvent() (Event, *WayPoint, error) {
+	for {
+		tok, err := d.xd.Token()
+		if err == io.EOF {
+			return EventEOF, nil, io.EOF
+		}
+		if err != nil {
+			return EventEOF, nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if ok {
+			switch start.Name.Local {
+			case "trk":
+				return EventTrackStart, nil, nil
+			case "trkseg":
+				return EventSegmentStart, nil, nil
+			case "rte":
+				return EventRouteStart, nil, nil
+			case "trkpt":
+				var p WayPoint
+				if err := d.xd.DecodeElement(&p, &start); err != nil {
+					return EventEOF, nil, err
+				}
+				return EventTrackPoint, &p, nil
+			case "wpt":
+				var p WayPoint
+				if err := d.xd.DecodeElement(&p, &start); err != nil {
+					return EventEOF, nil, err
+				}
+				return EventWaypoint, &p, nil
+			case "rtept":
+				var p WayPoint
+				if err := d.xd.DecodeElement(&p, &start); err != nil {
+					return EventEOF, nil, err
+				}
+				return EventRoutePoint, &p, nil
+			}
+			continue
+		}
+
+		if end, ok := tok.(xml.EndElement); ok {
+			switch end.Name.Local {
+			case "trk":
+				return EventTrackEnd, nil, nil
+			case "trkseg":
+				return EventSegmentEnd, nil, nil
+			case "rte":
+				return EventRouteEnd, nil, nil
+			}
+		}
+	}
+}
+
+// NextTrackPoint returns the next <trkpt> in the document, skipping over
+// any other events. It returns io.EOF once there are no more.
+func (d *Decoder) NextTrackPoint() (*WayPoint, error) {
+	return d.next(EventTrackPoint)
+}
+
+// NextWaypoint returns the next <wpt> in the document, skipping over any
+// other events. It returns io.EOF once there are no more.
+func (d *Decoder) NextWaypoint() (*WayPoint, error) {
+	return d.next(EventWaypoint)
+}
+
+// NextRoutePoint returns the next <rtept> in the document, skipping over
+// any other events. It returns io.EOF once there are no more.
+func (d *Decoder) NextRoutePoint() (*WayPoint, error) {
+	return d.next(EventRoutePoint)
+}
+
+func (d *Decoder) next(want Event) (*WayPoint, error) {
+	for {
+		event, point, err := d.NextEvent()
+		if err != nil {
+			return nil, err
+		}
+		if event == want {
+			return point, nil
+		}
+	}
+}
+
+// Decode reads a whole GPX 1.1 document from the decoder's underlying
+// stream into g. It's what Parse uses under the hood for documents small
+// enough to buffer entirely; NextEvent/NextTrackPoint and friends are the
+// point-at-a-time alternative for documents that aren't.
+func (d *Decoder) Decode(g *GPX) error {
+	return d.xd.Decode(g)
+}
+
+// Decode10 reads a whole GPX 1.0 document from the decoder's underlying
+// stream into g10. It's what Parse uses under the hood when it sniffs a
+// "1.0" version attribute on the root element.
+func (d *Decoder) Decode10(g10 *GPX10) error {
+	return d.xd.Decode(g10)
+}
+
+// ParseStream walks r's trackpoints one at a time, invoking handler for
+// each. It stops and returns handler's error if handler returns one.
+func ParseStream(r io.Reader, handler func(TrackPoint) error) error {
+	d := NewDecoder(r)
+	for {
+		p, err := d.NextTrackPoint()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := handler(*p); err != nil {
+			return err
+		}
+	}
+}
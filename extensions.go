@@ -0,0 +1,242 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Namespace URIs for the Garmin extension schemas used inside <extensions>.
+const (
+	nsGpxTPX = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+	nsGpxX   = "http://www.garmin.com/xmlschemas/GpxExtensions/v3"
+)
+
+// Extension holds the children of a waypoint/route/track/segment's
+// <extensions> element. The Garmin TrackPointExtension and
+// RouteExtension/TrackExtension elements are recognised by namespace URI
+// (regardless of which prefix the document declares for it) and decoded
+// into their typed fields below. Anything else is kept verbatim in
+// InnerXML so that round-tripping a file through Parse/Marshal doesn't
+// silently drop extensions this package doesn't know about.
+type Extension struct {
+	TrackPointExtension *TrackPointExtension
+	RouteExtension      *RouteExtension
+	TrackExtension      *TrackExtension
+	InnerXML            string
+}
+
+// TrackPointExtension carries the Garmin TrackPointExtension/v1 fields
+// found on a <trkpt>: temperature, heart rate, cadence, speed and course.
+type TrackPointExtension struct {
+	Temperature *float64 `xml:"atemp,omitempty"`
+	HeartRate   *int     `xml:"hr,omitempty"`
+	Cadence     *int     `xml:"cad,omitempty"`
+	Speed       *float64 `xml:"speed,omitempty"`
+	Course      *float64 `xml:"course,omitempty"`
+}
+
+// RouteExtension carries Garmin GpxExtensions/v3 route-level metadata.
+type RouteExtension struct {
+	DisplayColor string `xml:"DisplayColor,omitempty"`
+}
+
+// TrackExtension carries Garmin GpxExtensions/v3 track-level metadata.
+type TrackExtension struct {
+	DisplayColor string `xml:"DisplayColor,omitempty"`
+}
+
+// MarshalXML emits TrackPointExtension's fields with their gpxtpx: prefix
+// (<gpxtpx:atemp>, <gpxtpx:hr>, ...), matching the real Garmin/Strava
+// shape. A plain struct-tag-driven marshal can't do this: the unmarshal
+// side deliberately matches child elements by local name only (see
+// UnmarshalXML on Extension), so the field tags can't also carry the
+// prefix without breaking that match.
+func (tpe TrackPointExtension) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeOptional(enc, "gpxtpx:atemp", tpe.Temperature); err != nil {
+		return err
+	}
+	if err := encodeOptional(enc, "gpxtpx:hr", tpe.HeartRate); err != nil {
+		return err
+	}
+	if err := encodeOptional(enc, "gpxtpx:cad", tpe.Cadence); err != nil {
+		return err
+	}
+	if err := encodeOptional(enc, "gpxtpx:speed", tpe.Speed); err != nil {
+		return err
+	}
+	if err := encodeOptional(enc, "gpxtpx:course", tpe.Course); err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// MarshalXML emits RouteExtension's fields with their gpxx: prefix.
+func (re RouteExtension) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if re.DisplayColor != "" {
+		if err := enc.EncodeElement(re.DisplayColor, xml.StartElement{Name: xml.Name{Local: "gpxx:DisplayColor"}}); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// MarshalXML emits TrackExtension's fields with their gpxx: prefix.
+func (te TrackExtension) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if te.DisplayColor != "" {
+		if err := enc.EncodeElement(te.DisplayColor, xml.StartElement{Name: xml.Name{Local: "gpxx:DisplayColor"}}); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// encodeOptional emits <name>*v</name> under enc, or nothing if v is nil.
+func encodeOptional[T any](enc *xml.Encoder, name string, v *T) error {
+	if v == nil {
+		return nil
+	}
+	return enc.EncodeElement(*v, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// IsZero reports whether e has no extensions at all, known or otherwise.
+func (e Extension) IsZero() bool {
+	return e.TrackPointExtension == nil && e.RouteExtension == nil && e.TrackExtension == nil && e.InnerXML == ""
+}
+
+// UnmarshalXML walks the raw tokens of an <extensions> element rather than
+// relying on struct tags, because Go's encoding/xml matches child elements
+// by local name only when the parent isn't itself namespace-qualified -
+// matching by (Space, Local) here is what lets gpxtpx:TrackPointExtension
+// and an unprefixed TrackPointExtension both resolve correctly.
+func (e *Extension) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var unknown bytes.Buffer
+	enc := xml.NewEncoder(&unknown)
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "TrackPointExtension" && (t.Name.Space == nsGpxTPX || t.Name.Space == ""):
+				var tpe TrackPointExtension
+				if err := d.DecodeElement(&tpe, &t); err != nil {
+					return err
+				}
+				e.TrackPointExtension = &tpe
+			case t.Name.Local == "RouteExtension" && (t.Name.Space == nsGpxX || t.Name.Space == ""):
+				var re RouteExtension
+				if err := d.DecodeElement(&re, &t); err != nil {
+					return err
+				}
+				e.RouteExtension = &re
+			case t.Name.Local == "TrackExtension" && (t.Name.Space == nsGpxX || t.Name.Space == ""):
+				var te TrackExtension
+				if err := d.DecodeElement(&te, &t); err != nil {
+					return err
+				}
+				e.TrackExtension = &te
+			default:
+				if err := copyElement(d, enc, t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			e.InnerXML = unknown.String()
+			return nil
+		}
+	}
+}
+
+// copyElement re-emits start and everything up to and including its
+// matching end element into enc, preserving unrecognised extension XML
+// byte-for-byte (modulo namespace prefix rewriting by the encoder).
+func copyElement(d *xml.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start.Copy()); err != nil {
+		return err
+	}
+	depth := 1
+	for depth > 0 {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+// MarshalXML emits <extensions>, using gpxtpx:/gpxx: prefixes for the
+// Garmin elements this package understands and replaying InnerXML verbatim
+// for anything else.
+func (e Extension) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if e.IsZero() {
+		return nil
+	}
+
+	start.Name = xml.Name{Local: "extensions"}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if e.TrackPointExtension != nil {
+		el := xml.StartElement{Name: xml.Name{Local: "gpxtpx:TrackPointExtension"}}
+		if err := enc.EncodeElement(e.TrackPointExtension, el); err != nil {
+			return err
+		}
+	}
+	if e.RouteExtension != nil {
+		el := xml.StartElement{Name: xml.Name{Local: "gpxx:RouteExtension"}}
+		if err := enc.EncodeElement(e.RouteExtension, el); err != nil {
+			return err
+		}
+	}
+	if e.TrackExtension != nil {
+		el := xml.StartElement{Name: xml.Name{Local: "gpxx:TrackExtension"}}
+		if err := enc.EncodeElement(e.TrackExtension, el); err != nil {
+			return err
+		}
+	}
+	if e.InnerXML != "" {
+		dec := xml.NewDecoder(strings.NewReader(e.InnerXML))
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
@@ -0,0 +1,78 @@
+package gpx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestParseStravaExtensions verifies that a real Strava-style export with
+// prefixed gpxtpx:TrackPointExtension children decodes into typed fields
+// instead of being silently dropped.
+func TestParseStravaExtensions(t *testing.T) {
+	data, err := os.ReadFile("testdata/strava_sample.gpx")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	g := GPX{}
+	if err := Parse(data, &g); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(g.Tracks.TrackSegments) != 1 || len(g.Tracks.TrackSegments[0].TrackPoint) != 3 {
+		t.Fatalf("expected 1 segment of 3 trackpoints, got %+v", g.Tracks.TrackSegments)
+	}
+
+	first := g.Tracks.TrackSegments[0].TrackPoint[0]
+	tpe := first.Extensions.TrackPointExtension
+	if tpe == nil {
+		t.Fatalf("expected TrackPointExtension to be decoded, got nil")
+	}
+	if tpe.HeartRate == nil || *tpe.HeartRate != 109 {
+		t.Errorf("HeartRate = %v, want 109", tpe.HeartRate)
+	}
+	if tpe.Cadence == nil || *tpe.Cadence != 85 {
+		t.Errorf("Cadence = %v, want 85", tpe.Cadence)
+	}
+	if tpe.Temperature == nil || *tpe.Temperature != 21.0 {
+		t.Errorf("Temperature = %v, want 21.0", tpe.Temperature)
+	}
+}
+
+// TestMarshalStravaExtensionsRoundTrip verifies that a parsed
+// TrackPointExtension is re-marshaled with the gpxtpx: prefix on its
+// children, not just on the wrapper element, and that the values survive
+// a parse/marshal/parse round trip.
+func TestMarshalStravaExtensionsRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/strava_sample.gpx")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	g := GPX{}
+	if err := Parse(data, &g); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	out, err := Marshal(&g)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	for _, want := range []string{"<gpxtpx:atemp>", "<gpxtpx:hr>", "<gpxtpx:cad>"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("marshaled output missing %s, got: %s", want, out)
+		}
+	}
+
+	roundTripped := GPX{}
+	if err := Parse(out, &roundTripped); err != nil {
+		t.Fatalf("re-parsing marshaled output: %v", err)
+	}
+
+	tpe := roundTripped.Tracks.TrackSegments[0].TrackPoint[0].Extensions.TrackPointExtension
+	if tpe == nil || tpe.HeartRate == nil || *tpe.HeartRate != 109 {
+		t.Errorf("round-tripped HeartRate = %+v, want 109", tpe)
+	}
+}
@@ -1,6 +1,7 @@
 package gpx
 
 import (
+	"bytes"
 	"encoding/xml"
 	"io/ioutil"
 )
@@ -9,38 +10,61 @@ import (
 func ParseFile(fileName string) (*GPX, error) {
 	g := GPX{}
 
-	bytes, err := ioutil.ReadFile(fileName)
+	b, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return &g, err
 	}
 
-	err = Parse(bytes, &g)
+	err = Parse(b, &g)
 	if err != nil {
 		return &g, err
 	}
 	return &g, nil
 }
 
-// Parse bytes of xml
-func Parse(bytes []byte, g *GPX) error {
-	err := xml.Unmarshal(bytes, g)
-	if err != nil {
+// Parse bytes of xml. It sniffs the root element's version attribute and,
+// if it finds "1.0", parses as GPX 1.0 and converts the result into the
+// unified GPX model via Convert10to11. Anything else is parsed directly
+// as GPX 1.1. Parse and ParseFile are thin, buffered wrappers over the
+// same Decoder that NewDecoder/NextEvent use for streaming; callers
+// working with documents too large to hold in memory should use those
+// directly instead.
+func Parse(b []byte, g *GPX) error {
+	var probe struct {
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(b, &probe); err != nil {
 		return err
 	}
-	return nil
+
+	if probe.Version == "1.0" {
+		g10 := GPX10{}
+		if err := NewDecoder(bytes.NewReader(b)).Decode10(&g10); err != nil {
+			return err
+		}
+		*g = *Convert10to11(&g10)
+		return nil
+	}
+
+	return NewDecoder(bytes.NewReader(b)).Decode(g)
 }
 
 // Comments from http://www.topografix.com/GPX/1/1/
 
 // GPX is the root element
 type GPX struct {
-	XMLName   xml.Name   `xml:"gpx"`
-	Version   string     `xml:"version,attr"`
-	Creator   string     `xml:"creator,attr"`
-	Metadata  Metadata   `xml:"metadata,omitempty"`
-	Waypoints []WayPoint `xml:"wpt,omitempty"`
-	Routes    []Route    `xml:"rte,omitempty"`
-	Tracks    Track      `xml:"trk"`
+	XMLName        xml.Name   `xml:"gpx"`
+	Version        string     `xml:"version,attr"`
+	Creator        string     `xml:"creator,attr"`
+	XMLNs          string     `xml:"xmlns,attr,omitempty"`
+	XMLNsXSI       string     `xml:"xmlns:xsi,attr,omitempty"`
+	XMLNsGpxTPX    string     `xml:"xmlns:gpxtpx,attr,omitempty"`
+	XMLNsGpxX      string     `xml:"xmlns:gpxx,attr,omitempty"`
+	SchemaLocation string     `xml:"xsi:schemaLocation,attr,omitempty"`
+	Metadata       Metadata   `xml:"metadata,omitempty"`
+	Waypoints      []WayPoint `xml:"wpt,omitempty"`
+	Routes         []Route    `xml:"rte,omitempty"`
+	Tracks         Track      `xml:"trk"`
 }
 
 // Metadata has information about the GPX file
@@ -51,18 +75,38 @@ type Metadata struct {
 	Author      Person    `xml:"author,omitempty"`
 	Copyright   Copyright `xml:"copyright,omitempty"`
 	Links       []Link    `xml:"link,omitempty"`
-	Timestamp   string    `xml:"time,omitempty"`
+	Timestamp   Timestamp `xml:"time,omitempty"`
 	Keywords    string    `xml:"keywords,omitempty"`
 	Bounds      Bounds    `xml:"bounds"`
 	Extensions  Extension `xml:"extensions,omitempty"`
 }
 
+// IsZero reports whether m has no content at all, so callers (and
+// Metadata's own MarshalXML) can tell an empty Metadata from one worth
+// emitting.
+func (m Metadata) IsZero() bool {
+	return m.Name == "" && m.Description == "" && m.Author.IsZero() && m.Copyright.IsZero() &&
+		len(m.Links) == 0 && m.Timestamp.IsZero() && m.Keywords == "" && m.Bounds.IsZero() && m.Extensions.IsZero()
+}
+
+// MarshalXML skips emitting <metadata> entirely when m is empty.
+// encoding/xml's omitempty has no effect on struct-typed fields, so
+// without this a zero-value GPX.Metadata would otherwise marshal as a
+// <metadata> full of empty child elements.
+func (m Metadata) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if m.IsZero() {
+		return nil
+	}
+	type metadata Metadata
+	return enc.EncodeElement(metadata(m), start)
+}
+
 // WayPoint is a point of interest, or named feature on a map.
 type WayPoint struct {
 	Latitude                      Latitude    `xml:"lat,attr"`
 	Longitude                     Longitude   `xml:"lon,attr"`
 	Elevation                     float64     `xml:"ele,omitempty"`
-	Timestamp                     string      `xml:"time,omitempty"`
+	Timestamp                     Timestamp   `xml:"time,omitempty"`
 	MagneticVariation             Degrees     `xml:"magvar,omitempty"`
 	GeoIDHeight                   string      `xml:"geoidheight,omitempty"`
 	Name                          string      `xml:"name,omitempty"`
@@ -80,6 +124,12 @@ type WayPoint struct {
 	AgeOfGpsData                  float64     `xml:"ageofgpsdata,omitempty"`
 	DifferentialGPSID             DGPSStation `xml:"dgpsid,omitempty"`
 	Extensions                    Extension   `xml:"extensions,omitempty"`
+
+	// Course and Speed only exist in GPX 1.0. They're carried on the unified
+	// WayPoint so a document can round-trip through Convert10to11/Convert11to10
+	// without losing data; they're ignored when marshalling as GPX 1.1.
+	Course *Degrees `xml:"-"`
+	Speed  *float64 `xml:"-"`
 }
 
 // Route is an ordered list of Waypoints representing a series of points leading to a destination.
@@ -110,19 +160,9 @@ type Track struct {
 	TrackSegments []TrackSegment `xml:"trkseg"`
 }
 
-// Extension extend GPX by adding your own elements from another schema
-type Extension struct {
-	XMLName              xml.Name            `xml:"extensions"`
-	TrackPointExtensions TrackPointExtension `xml:"TrackPointExtension,omitempty"`
-}
-
-// TrackPointExtension tracks temperature, heart rate and cadence specific to garmin devices
-type TrackPointExtension struct {
-	XMLName     xml.Name `xml:"TrackPointExtension"`
-	Temperature int      `xml:"atemp,omitempty"`
-	HeartRate   int      `xml:"hr,omitempty"`
-	Cadence     int      `xml:"cad,omitempty"`
-}
+// Extension and its Garmin-specific children (TrackPointExtension,
+// RouteExtension, TrackExtension) live in extensions.go, since they need
+// custom namespace-aware (Un)MarshalXML rather than plain struct tags.
 
 // TrackSegment has a list of continious span of TrackPoints
 type TrackSegment struct {
@@ -139,6 +179,20 @@ type Copyright struct {
 	License string   `xml:"license,omitempty"`
 }
 
+// IsZero reports whether c has no content at all.
+func (c Copyright) IsZero() bool {
+	return c.Author == "" && c.Year == "" && c.License == ""
+}
+
+// MarshalXML skips emitting <copyright> entirely when c is empty.
+func (c Copyright) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if c.IsZero() {
+		return nil
+	}
+	type copyright Copyright
+	return enc.EncodeElement(copyright(c), start)
+}
+
 // Link is for an external resource with additional information.
 type Link struct {
 	XMLName xml.Name `xml:"link"`
@@ -147,6 +201,20 @@ type Link struct {
 	Type    string   `xml:"type,omitempty"`
 }
 
+// IsZero reports whether l has no content at all.
+func (l Link) IsZero() bool {
+	return l.URL == "" && l.Text == "" && l.Type == ""
+}
+
+// MarshalXML skips emitting <link> entirely when l is empty.
+func (l Link) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if l.IsZero() {
+		return nil
+	}
+	type link Link
+	return enc.EncodeElement(link(l), start)
+}
+
 // Email address which is broken into two parts (id and domain)
 type Email struct {
 	XMLName xml.Name `xml:"email"`
@@ -154,6 +222,20 @@ type Email struct {
 	Domain  string   `xml:"domain,attr,omitempty"`
 }
 
+// IsZero reports whether e has no content at all.
+func (e Email) IsZero() bool {
+	return e.ID == "" && e.Domain == ""
+}
+
+// MarshalXML skips emitting <email> entirely when e is empty.
+func (e Email) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if e.IsZero() {
+		return nil
+	}
+	type email Email
+	return enc.EncodeElement(email(e), start)
+}
+
 // Person is a person or an organisation
 type Person struct {
 	XMLName xml.Name `xml:"author"`
@@ -162,13 +244,27 @@ type Person struct {
 	Link    Link     `xml:"link,omitempty"`
 }
 
+// IsZero reports whether p has no content at all.
+func (p Person) IsZero() bool {
+	return p.Name == "" && p.Email.IsZero() && p.Link.IsZero()
+}
+
+// MarshalXML skips emitting <author> entirely when p is empty.
+func (p Person) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if p.IsZero() {
+		return nil
+	}
+	type person Person
+	return enc.EncodeElement(person(p), start)
+}
+
 // Point with optional elevation and time
 type Point struct {
 	XMLName   xml.Name  `xml:"pt"`
 	Latitude  Latitude  `xml:"lat,attr"`
 	Longitude Longitude `xml:"lon,attr"`
 	Elevation float64   `xml:"ele,omitempty"`
-	Timestamp string    `xml:"time,omitempty"`
+	Timestamp Timestamp `xml:"time,omitempty"`
 }
 
 // PointSegment is a sequence of Points
@@ -186,6 +282,20 @@ type Bounds struct {
 	MaxLon  float64  `xml:"maxlon,attr"`
 }
 
+// IsZero reports whether b is the zero bounding box.
+func (b Bounds) IsZero() bool {
+	return b.MinLat == 0 && b.MaxLat == 0 && b.MinLon == 0 && b.MaxLon == 0
+}
+
+// MarshalXML skips emitting <bounds> entirely when b is zero.
+func (b Bounds) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if b.IsZero() {
+		return nil
+	}
+	type bounds Bounds
+	return enc.EncodeElement(bounds(b), start)
+}
+
 // Latitude is the latitude of the point. Decimal degrees, WGS84 datum. The value varies between -90.0 to 90.0
 type Latitude float64
 
@@ -0,0 +1,231 @@
+package gpx
+
+import "encoding/xml"
+
+// GPX 1.0 uses flat author/email/url/urlname scalars on the root element
+// instead of the 1.1 metadata/link structure, and adds course/speed to
+// trackpoints. GPX10 and its point types mirror that schema; Convert10to11
+// and Convert11to10 translate between it and the unified GPX model used
+// for 1.1.
+
+// GPX10 is the root element of a GPX 1.0 document.
+type GPX10 struct {
+	XMLName     xml.Name     `xml:"gpx"`
+	Version     string       `xml:"version,attr"`
+	Creator     string       `xml:"creator,attr"`
+	Name        string       `xml:"name,omitempty"`
+	Description string       `xml:"desc,omitempty"`
+	Author      string       `xml:"author,omitempty"`
+	Email       string       `xml:"email,omitempty"`
+	URL         string       `xml:"url,omitempty"`
+	URLName     string       `xml:"urlname,omitempty"`
+	Timestamp   string       `xml:"time,omitempty"`
+	Keywords    string       `xml:"keywords,omitempty"`
+	Bounds      Bounds       `xml:"bounds"`
+	Waypoints   []WayPoint10 `xml:"wpt,omitempty"`
+	Routes      []Route10    `xml:"rte,omitempty"`
+	Tracks      []Track10    `xml:"trk,omitempty"`
+}
+
+// WayPoint10 is a GPX 1.0 point of interest, route point, or trackpoint.
+type WayPoint10 struct {
+	Latitude                      Latitude    `xml:"lat,attr"`
+	Longitude                     Longitude   `xml:"lon,attr"`
+	Elevation                     float64     `xml:"ele,omitempty"`
+	Timestamp                     string      `xml:"time,omitempty"`
+	Course                        Degrees     `xml:"course,omitempty"`
+	Speed                         float64     `xml:"speed,omitempty"`
+	MagneticVariation             Degrees     `xml:"magvar,omitempty"`
+	GeoIDHeight                   string      `xml:"geoidheight,omitempty"`
+	Name                          string      `xml:"name,omitempty"`
+	Comment                       string      `xml:"cmt,omitempty"`
+	Description                   string      `xml:"desc,omitempty"`
+	Source                        string      `xml:"src,omitempty"`
+	URL                           string      `xml:"url,omitempty"`
+	URLName                       string      `xml:"urlname,omitempty"`
+	Symbol                        string      `xml:"sym,omitempty"`
+	Type                          string      `xml:"type,omitempty"`
+	Fix                           Fix         `xml:"fix,omitempty"`
+	Sat                           int         `xml:"sat,omitempty"`
+	HorizontalDilutionOfPrecision float64     `xml:"hdop,omitempty"`
+	VerticalDilutionOfPrecision   float64     `xml:"vdop,omitempty"`
+	PositionDilutionOfPrecision   float64     `xml:"pdop,omitempty"`
+	AgeOfGpsData                  float64     `xml:"ageofgpsdata,omitempty"`
+	DifferentialGPSID             DGPSStation `xml:"dgpsid,omitempty"`
+}
+
+// Route10 is a GPX 1.0 route.
+type Route10 struct {
+	XMLName     xml.Name     `xml:"rte"`
+	Name        string       `xml:"name,omitempty"`
+	Comment     string       `xml:"cmt,omitempty"`
+	Description string       `xml:"desc,omitempty"`
+	Source      string       `xml:"src,omitempty"`
+	URL         string       `xml:"url,omitempty"`
+	URLName     string       `xml:"urlname,omitempty"`
+	Number      int          `xml:"number,omitempty"`
+	RoutePoints []WayPoint10 `xml:"rtept"`
+}
+
+// Track10 is a GPX 1.0 track.
+type Track10 struct {
+	XMLName       xml.Name         `xml:"trk"`
+	Name          string           `xml:"name,omitempty"`
+	Comment       string           `xml:"cmt,omitempty"`
+	Description   string           `xml:"desc,omitempty"`
+	Source        string           `xml:"src,omitempty"`
+	URL           string           `xml:"url,omitempty"`
+	URLName       string           `xml:"urlname,omitempty"`
+	Number        int              `xml:"number,omitempty"`
+	TrackSegments []TrackSegment10 `xml:"trkseg"`
+}
+
+// TrackSegment10 is a contiguous span of GPX 1.0 trackpoints.
+type TrackSegment10 struct {
+	XMLName    xml.Name     `xml:"trkseg"`
+	TrackPoint []WayPoint10 `xml:"trkpt"`
+}
+
+// Convert10to11 builds the unified GPX model from a parsed GPX 1.0 document.
+// Course and Speed are preserved on the unified WayPoint even though GPX 1.1
+// has no such fields, so a round trip back through Convert11to10 doesn't
+// lose data. A GPX 1.0 document with multiple <trk> elements has all of
+// its segments folded into the unified model's single Track, since that's
+// all the unified model has room for; only the first track's name/comment/
+// etc are kept.
+func Convert10to11(g10 *GPX10) *GPX {
+	g := &GPX{
+		Version: "1.1",
+		Creator: g10.Creator,
+		Metadata: Metadata{
+			Name:        g10.Name,
+			Description: g10.Description,
+			Timestamp:   mustParseTimestamp(g10.Timestamp),
+			Keywords:    g10.Keywords,
+			Bounds:      g10.Bounds,
+			Author: Person{
+				Name:  g10.Author,
+				Email: Email{ID: g10.Email},
+				Link:  Link{URL: g10.URL, Text: g10.URLName},
+			},
+		},
+	}
+
+	for _, w := range g10.Waypoints {
+		g.Waypoints = append(g.Waypoints, convertWayPoint10to11(w))
+	}
+	for _, r := range g10.Routes {
+		route := Route{Name: r.Name, Comment: r.Comment, Description: r.Description, Source: r.Source, Number: r.Number}
+		if r.URL != "" || r.URLName != "" {
+			route.Links = []Link{{URL: r.URL, Text: r.URLName}}
+		}
+		for _, w := range r.RoutePoints {
+			route.RoutePoints = append(route.RoutePoints, convertWayPoint10to11(w))
+		}
+		g.Routes = append(g.Routes, route)
+	}
+	// The unified model carries a single Track, but a GPX 1.0 document can
+	// have several <trk> elements. Rather than silently dropping every
+	// track after the first, take the first track's name/comment/etc and
+	// append every track's segments onto it, so no trackpoint is lost.
+	for i, t := range g10.Tracks {
+		if i == 0 {
+			g.Tracks = Track{Name: t.Name, Comment: t.Comment, Description: t.Description, Source: t.Source, Number: t.Number}
+			if t.URL != "" || t.URLName != "" {
+				g.Tracks.Links = []Link{{URL: t.URL, Text: t.URLName}}
+			}
+		}
+		for _, s := range t.TrackSegments {
+			seg := TrackSegment{}
+			for _, w := range s.TrackPoint {
+				seg.TrackPoint = append(seg.TrackPoint, convertWayPoint10to11(w))
+			}
+			g.Tracks.TrackSegments = append(g.Tracks.TrackSegments, seg)
+		}
+	}
+	return g
+}
+
+// Convert11to10 builds a GPX 1.0 document from the unified GPX model.
+// Only the first track is kept, since GPX 1.0 documents in the wild
+// conventionally carry a single track.
+func Convert11to10(g *GPX) *GPX10 {
+	g10 := &GPX10{
+		Version:     "1.0",
+		Creator:     g.Creator,
+		Name:        g.Metadata.Name,
+		Description: g.Metadata.Description,
+		Author:      g.Metadata.Author.Name,
+		Email:       g.Metadata.Author.Email.ID,
+		URL:         g.Metadata.Author.Link.URL,
+		URLName:     g.Metadata.Author.Link.Text,
+		Timestamp:   formatTimestamp(g.Metadata.Timestamp),
+		Keywords:    g.Metadata.Keywords,
+		Bounds:      g.Metadata.Bounds,
+	}
+
+	for _, w := range g.Waypoints {
+		g10.Waypoints = append(g10.Waypoints, convertWayPoint11to10(w))
+	}
+	for _, r := range g.Routes {
+		route := Route10{Name: r.Name, Comment: r.Comment, Description: r.Description, Source: r.Source, Number: r.Number}
+		if len(r.Links) > 0 {
+			route.URL, route.URLName = r.Links[0].URL, r.Links[0].Text
+		}
+		for _, w := range r.RoutePoints {
+			route.RoutePoints = append(route.RoutePoints, convertWayPoint11to10(w))
+		}
+		g10.Routes = append(g10.Routes, route)
+	}
+	if g.Tracks.TrackSegments != nil || g.Tracks.Name != "" {
+		t := Track10{Name: g.Tracks.Name, Comment: g.Tracks.Comment, Description: g.Tracks.Description, Source: g.Tracks.Source, Number: g.Tracks.Number}
+		if len(g.Tracks.Links) > 0 {
+			t.URL, t.URLName = g.Tracks.Links[0].URL, g.Tracks.Links[0].Text
+		}
+		for _, s := range g.Tracks.TrackSegments {
+			seg := TrackSegment10{}
+			for _, w := range s.TrackPoint {
+				seg.TrackPoint = append(seg.TrackPoint, convertWayPoint11to10(w))
+			}
+			t.TrackSegments = append(t.TrackSegments, seg)
+		}
+		g10.Tracks = append(g10.Tracks, t)
+	}
+	return g10
+}
+
+func convertWayPoint10to11(w WayPoint10) WayPoint {
+	out := WayPoint{
+		Latitude: w.Latitude, Longitude: w.Longitude, Elevation: w.Elevation, Timestamp: mustParseTimestamp(w.Timestamp),
+		MagneticVariation: w.MagneticVariation, GeoIDHeight: w.GeoIDHeight, Name: w.Name, Comment: w.Comment,
+		Description: w.Description, Source: w.Source, Symbol: w.Symbol, Type: w.Type, Fix: w.Fix, Sat: w.Sat,
+		HorizontalDilutionOfPrecision: w.HorizontalDilutionOfPrecision, VerticalDilutionOfPrecision: w.VerticalDilutionOfPrecision,
+		PositionDilutionOfPrecision: w.PositionDilutionOfPrecision, AgeOfGpsData: w.AgeOfGpsData, DifferentialGPSID: w.DifferentialGPSID,
+	}
+	if w.URL != "" || w.URLName != "" {
+		out.Links = []Link{{URL: w.URL, Text: w.URLName}}
+	}
+	course, speed := w.Course, w.Speed
+	out.Course, out.Speed = &course, &speed
+	return out
+}
+
+func convertWayPoint11to10(w WayPoint) WayPoint10 {
+	out := WayPoint10{
+		Latitude: w.Latitude, Longitude: w.Longitude, Elevation: w.Elevation, Timestamp: formatTimestamp(w.Timestamp),
+		MagneticVariation: w.MagneticVariation, GeoIDHeight: w.GeoIDHeight, Name: w.Name, Comment: w.Comment,
+		Description: w.Description, Source: w.Source, Symbol: w.Symbol, Type: w.Type, Fix: w.Fix, Sat: w.Sat,
+		HorizontalDilutionOfPrecision: w.HorizontalDilutionOfPrecision, VerticalDilutionOfPrecision: w.VerticalDilutionOfPrecision,
+		PositionDilutionOfPrecision: w.PositionDilutionOfPrecision, AgeOfGpsData: w.AgeOfGpsData, DifferentialGPSID: w.DifferentialGPSID,
+	}
+	if len(w.Links) > 0 {
+		out.URL, out.URLName = w.Links[0].URL, w.Links[0].Text
+	}
+	if w.Course != nil {
+		out.Course = *w.Course
+	}
+	if w.Speed != nil {
+		out.Speed = *w.Speed
+	}
+	return out
+}
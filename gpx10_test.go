@@ -0,0 +1,35 @@
+package gpx
+
+import "testing"
+
+// TestConvert10to11MultipleTracks verifies that a GPX 1.0 document with
+// more than one <trk> doesn't lose any trackpoints when folded into the
+// unified model's single Track.
+func TestConvert10to11MultipleTracks(t *testing.T) {
+	g10 := &GPX10{
+		Tracks: []Track10{
+			{
+				Name: "first",
+				TrackSegments: []TrackSegment10{
+					{TrackPoint: []WayPoint10{{Latitude: 1, Longitude: 1}}},
+				},
+			},
+			{
+				Name: "second",
+				TrackSegments: []TrackSegment10{
+					{TrackPoint: []WayPoint10{{Latitude: 2, Longitude: 2}}},
+					{TrackPoint: []WayPoint10{{Latitude: 3, Longitude: 3}}},
+				},
+			},
+		},
+	}
+
+	g := Convert10to11(g10)
+
+	if g.Tracks.Name != "first" {
+		t.Errorf("expected the first track's name to be kept, got %q", g.Tracks.Name)
+	}
+	if len(g.Tracks.TrackSegments) != 3 {
+		t.Fatalf("expected all 3 segments across both tracks to be kept, got %d", len(g.Tracks.TrackSegments))
+	}
+}
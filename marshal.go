@@ -0,0 +1,171 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+)
+
+// Namespace URIs written onto the root <gpx> element so output validates
+// against the GPX 1.1 schema and the Garmin extension schemas used by
+// TrackPointExtension and RouteExtension/TrackExtension.
+const (
+	xmlnsGPX11     = "http://www.topografix.com/GPX/1/1"
+	xmlnsXSI       = "http://www.w3.org/2001/XMLSchema-instance"
+	xmlnsGpxTPX    = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+	xmlnsGpxX      = "http://www.garmin.com/xmlschemas/GpxExtensions/v3"
+	schemaLocGPX11 = xmlnsGPX11 + " http://www.topografix.com/GPX/1/1/gpx.xsd " +
+		xmlnsGpxTPX + " http://www.garmin.com/xmlschemas/TrackPointExtensionv1.xsd " +
+		xmlnsGpxX + " http://www.garmin.com/xmlschemas/GpxExtensionsv3.xsd"
+)
+
+// withNamespaces returns a shallow copy of g with the standard GPX 1.1 and
+// Garmin namespace attributes populated on the root element, so callers
+// building a GPX by hand don't need to know about them.
+func withNamespaces(g *GPX) *GPX {
+	out := *g
+	out.XMLNs = xmlnsGPX11
+	out.XMLNsXSI = xmlnsXSI
+	out.XMLNsGpxTPX = xmlnsGpxTPX
+	out.XMLNsGpxX = xmlnsGpxX
+	out.SchemaLocation = schemaLocGPX11
+	return &out
+}
+
+// Marshal serialises g to GPX 1.1 XML.
+func Marshal(g *GPX) ([]byte, error) {
+	return xml.Marshal(withNamespaces(g))
+}
+
+// MarshalIndent is like Marshal but indents the output using prefix and indent,
+// the same way xml.MarshalIndent does.
+func MarshalIndent(g *GPX, prefix, indent string) ([]byte, error) {
+	return xml.MarshalIndent(withNamespaces(g), prefix, indent)
+}
+
+// MarshalVersion serialises g targeting the given GPX version ("1.0" or
+// "1.1"), converting through Convert11to10 first when version is "1.0".
+func MarshalVersion(g *GPX, version string) ([]byte, error) {
+	if version == "1.0" {
+		return xml.Marshal(Convert11to10(g))
+	}
+	return Marshal(g)
+}
+
+// WriteFile marshals g as indented GPX 1.1 XML, preceded by the XML
+// declaration, and writes it to fileName.
+func WriteFile(fileName string, g *GPX) error {
+	body, err := MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	out := append([]byte(xml.Header), body...)
+	return ioutil.WriteFile(fileName, out, 0644)
+}
+
+// Encoder writes a GPX document to an underlying io.Writer one piece at a
+// time, so a track with millions of points can be produced without ever
+// holding the whole thing in memory. Callers call EncodeHeader once, then
+// EncodeSegment/EncodeTrackPoint/EndSegment for each track segment, then
+// Close.
+type Encoder struct {
+	w        io.Writer
+	xe       *xml.Encoder
+	trkStart xml.StartElement
+	segStart xml.StartElement
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, xe: xml.NewEncoder(w)}
+}
+
+// EncodeHeader writes the XML declaration, the opening <gpx> element with
+// its namespace attributes, g.Metadata, g.Waypoints, g.Routes, and opens
+// the <trk> element (including its name/cmt/etc, but not its segments).
+// Track points are streamed afterwards via EncodeSegment/EncodeTrackPoint.
+func (e *Encoder) EncodeHeader(g *GPX) error {
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+
+	start := xml.StartElement{
+		Name: xml.Name{Local: "gpx"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "version"}, Value: g.Version},
+			{Name: xml.Name{Local: "creator"}, Value: g.Creator},
+			{Name: xml.Name{Local: "xmlns"}, Value: xmlnsGPX11},
+			{Name: xml.Name{Local: "xmlns:xsi"}, Value: xmlnsXSI},
+			{Name: xml.Name{Local: "xmlns:gpxtpx"}, Value: xmlnsGpxTPX},
+			{Name: xml.Name{Local: "xmlns:gpxx"}, Value: xmlnsGpxX},
+			{Name: xml.Name{Local: "xsi:schemaLocation"}, Value: schemaLocGPX11},
+		},
+	}
+	if err := e.xe.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if !g.Metadata.IsZero() {
+		if err := e.xe.Encode(g.Metadata); err != nil {
+			return err
+		}
+	}
+	for i := range g.Waypoints {
+		if err := e.xe.EncodeElement(g.Waypoints[i], xml.StartElement{Name: xml.Name{Local: "wpt"}}); err != nil {
+			return err
+		}
+	}
+	for i := range g.Routes {
+		if err := e.xe.Encode(g.Routes[i]); err != nil {
+			return err
+		}
+	}
+
+	e.trkStart = xml.StartElement{Name: xml.Name{Local: "trk"}}
+	if err := e.xe.EncodeToken(e.trkStart); err != nil {
+		return err
+	}
+	if g.Tracks.Name != "" {
+		if err := e.xe.EncodeElement(g.Tracks.Name, xml.StartElement{Name: xml.Name{Local: "name"}}); err != nil {
+			return err
+		}
+	}
+	return e.xe.Flush()
+}
+
+// EncodeSegment opens a new <trkseg> element.
+func (e *Encoder) EncodeSegment() error {
+	e.segStart = xml.StartElement{Name: xml.Name{Local: "trkseg"}}
+	if err := e.xe.EncodeToken(e.segStart); err != nil {
+		return err
+	}
+	return e.xe.Flush()
+}
+
+// EncodeTrackPoint writes a single <trkpt> into the currently open segment.
+func (e *Encoder) EncodeTrackPoint(p WayPoint) error {
+	if err := e.xe.EncodeElement(p, xml.StartElement{Name: xml.Name{Local: "trkpt"}}); err != nil {
+		return err
+	}
+	return e.xe.Flush()
+}
+
+// EndSegment closes the currently open <trkseg> element.
+func (e *Encoder) EndSegment() error {
+	if err := e.xe.EncodeToken(e.segStart.End()); err != nil {
+		return err
+	}
+	return e.xe.Flush()
+}
+
+// Close closes the <trk> and <gpx> elements. It must be called exactly once,
+// after the last EndSegment.
+func (e *Encoder) Close() error {
+	if err := e.xe.EncodeToken(e.trkStart.End()); err != nil {
+		return err
+	}
+	if err := e.xe.EncodeToken(xml.EndElement{Name: xml.Name{Local: "gpx"}}); err != nil {
+		return err
+	}
+	return e.xe.Flush()
+}
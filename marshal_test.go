@@ -0,0 +1,25 @@
+package gpx
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMarshalEmptySubstructuresOmitted verifies that marshaling a GPX built
+// with only the fields a caller actually set doesn't emit the empty
+// <metadata>/<author>/<copyright>/<bounds> boilerplate that plain
+// struct-tag-driven marshaling of zero-value structs would produce.
+func TestMarshalEmptySubstructuresOmitted(t *testing.T) {
+	g := &GPX{Version: "1.1", Creator: "test"}
+
+	out, err := Marshal(g)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	for _, unwanted := range []string{"<metadata>", "<author>", "<copyright", "<bounds"} {
+		if strings.Contains(string(out), unwanted) {
+			t.Errorf("expected no %s in output for an empty GPX, got: %s", unwanted, out)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gpx
+
+import "math"
+
+// toRad converts decimal degrees to radians.
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+
+// bearingRad returns the initial bearing from a to b, in radians.
+func bearingRad(a, b WayPoint) float64 {
+	lat1, lon1 := toRad(float64(a.Latitude)), toRad(float64(a.Longitude))
+	lat2, lon2 := toRad(float64(b.Latitude)), toRad(float64(b.Longitude))
+
+	y := math.Sin(lon2-lon1) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(lon2-lon1)
+	return math.Atan2(y, x)
+}
+
+// crossTrackDistanceMeters returns the great-circle distance of p from the
+// chord between start and end, in meters, using the standard cross-track
+// distance formula: asin(sin(d13/R) * sin(θ13-θ12)) * R.
+func crossTrackDistanceMeters(p, start, end WayPoint) float64 {
+	d13 := haversineMeters(float64(start.Latitude), float64(start.Longitude), float64(p.Latitude), float64(p.Longitude)) / earthRadiusMeters
+	theta13 := bearingRad(start, p)
+	theta12 := bearingRad(start, end)
+	return math.Asin(math.Sin(d13)*math.Sin(theta13-theta12)) * earthRadiusMeters
+}
+
+// douglasPeucker implements the Ramer-Douglas-Peucker algorithm over a
+// polyline of WGS84 points: it keeps start and end, finds the point with
+// the greatest perpendicular (cross-track) distance from the chord between
+// them, and recurses on either side of it if that distance exceeds
+// epsilon; otherwise every point between start and end is dropped.
+func douglasPeucker(points []WayPoint, epsilonMeters float64) []WayPoint {
+	if len(points) < 3 {
+		return points
+	}
+
+	start, end := points[0], points[len(points)-1]
+	var maxDist float64
+	var idx int
+	for i := 1; i < len(points)-1; i++ {
+		if d := math.Abs(crossTrackDistanceMeters(points[i], start, end)); d > maxDist {
+			maxDist, idx = d, i
+		}
+	}
+
+	if maxDist <= epsilonMeters {
+		return []WayPoint{start, end}
+	}
+
+	left := douglasPeucker(points[:idx+1], epsilonMeters)
+	right := douglasPeucker(points[idx:], epsilonMeters)
+	return append(left[:len(left)-1:len(left)-1], right...)
+}
+
+// removeOutliers drops points whose implied speed from the preceding kept
+// point exceeds maxSpeedMps, since a single bad fix otherwise poisons every
+// distance/speed calculation that follows it.
+func removeOutliers(points []WayPoint, maxSpeedMps float64) []WayPoint {
+	if len(points) == 0 {
+		return points
+	}
+
+	kept := []WayPoint{points[0]}
+	for i := 1; i < len(points); i++ {
+		prev := kept[len(kept)-1]
+		elapsed := points[i].Timestamp.Sub(prev.Timestamp.Time).Seconds()
+		if elapsed <= 0 {
+			kept = append(kept, points[i])
+			continue
+		}
+		if distance2D(prev, points[i])/elapsed > maxSpeedMps {
+			continue
+		}
+		kept = append(kept, points[i])
+	}
+	return kept
+}
+
+// Simplify reduces s's trackpoints in place using the Douglas-Peucker
+// algorithm, dropping points within epsilonMeters of the simplified path.
+func (s *TrackSegment) Simplify(epsilonMeters float64) {
+	s.TrackPoint = douglasPeucker(s.TrackPoint, epsilonMeters)
+}
+
+// SmoothElevation replaces each point's elevation with a centered
+// moving-average over window points, to reject GPS/barometric noise.
+func (s *TrackSegment) SmoothElevation(window int) {
+	smoothed := smoothElevation(s.TrackPoint, window)
+	for i := range s.TrackPoint {
+		s.TrackPoint[i].Elevation = smoothed[i]
+	}
+}
+
+// RemoveOutliers drops points whose implied speed from the previous kept
+// point exceeds maxSpeedMps.
+func (s *TrackSegment) RemoveOutliers(maxSpeedMps float64) {
+	s.TrackPoint = removeOutliers(s.TrackPoint, maxSpeedMps)
+}
+
+// Simplify reduces every segment of t in place using the Douglas-Peucker
+// algorithm, dropping points within epsilonMeters of the simplified path.
+func (t *Track) Simplify(epsilonMeters float64) {
+	for i := range t.TrackSegments {
+		t.TrackSegments[i].Simplify(epsilonMeters)
+	}
+}
@@ -0,0 +1,32 @@
+package gpx
+
+import "testing"
+
+// TestDouglasPeuckerDropsPointsWithinEpsilon checks the textbook case: a
+// near-straight line with one point nudged off it. A large epsilon should
+// collapse the line to just its endpoints; a tiny one should keep the
+// off-line point.
+func TestDouglasPeuckerDropsPointsWithinEpsilon(t *testing.T) {
+	points := []WayPoint{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0.0001, Longitude: 1}, // a few meters off the start-end chord
+		{Latitude: 0, Longitude: 2},
+	}
+
+	if got := douglasPeucker(points, 1000); len(got) != 2 {
+		t.Errorf("with a generous epsilon, expected the line collapsed to 2 points, got %d", len(got))
+	}
+
+	if got := douglasPeucker(points, 0.001); len(got) != 3 {
+		t.Errorf("with a tiny epsilon, expected all 3 points kept, got %d", len(got))
+	}
+}
+
+// TestDouglasPeuckerKeepsShortPolylines checks the base case: fewer than 3
+// points is returned unchanged, regardless of epsilon.
+func TestDouglasPeuckerKeepsShortPolylines(t *testing.T) {
+	points := []WayPoint{{Latitude: 0, Longitude: 0}, {Latitude: 1, Longitude: 1}}
+	if got := douglasPeucker(points, 0); len(got) != 2 {
+		t.Errorf("expected a 2-point polyline unchanged, got %d points", len(got))
+	}
+}
@@ -0,0 +1,94 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Layouts tried in order when parsing an xsd:dateTime value, to tolerate
+// the fractional-second and timezone variations seen in real GPX files.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+}
+
+// Timestamp is an xsd:dateTime value such as a trackpoint's <time>. It
+// marshals as UTC RFC3339 regardless of the zone it was parsed with. Raw
+// keeps the exact string it was parsed from, as an escape hatch for
+// callers that need the original representation rather than a reformatted
+// one.
+type Timestamp struct {
+	time.Time
+	Raw string
+}
+
+// ParseTimestamp parses s as an xsd:dateTime value.
+func ParseTimestamp(s string) (Timestamp, error) {
+	var t Timestamp
+	err := t.parse(s)
+	return t, err
+}
+
+func (t *Timestamp) parse(s string) error {
+	t.Raw = s
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	var err error
+	for _, layout := range timestampLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return err
+}
+
+// mustParseTimestamp parses s, falling back to a zero Timestamp with Raw
+// set to s if it isn't a recognised xsd:dateTime - used when converting
+// GPX 1.0's plain string timestamps into the unified model, where a
+// malformed timestamp shouldn't fail the whole conversion.
+func mustParseTimestamp(s string) Timestamp {
+	t, err := ParseTimestamp(s)
+	if err != nil {
+		return Timestamp{Raw: s}
+	}
+	return t
+}
+
+// formatTimestamp renders t back to a GPX 1.0 string timestamp, preferring
+// the original raw text if t was parsed rather than constructed directly.
+func formatTimestamp(t Timestamp) string {
+	if t.Time.IsZero() {
+		return t.Raw
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// UnmarshalXML implements xml.Unmarshaler. A <time> value that isn't a
+// recognised xsd:dateTime doesn't fail the decode - these fields exist to
+// ingest messy real-world Garmin/Strava exports, and a single malformed
+// timestamp anywhere in a large file shouldn't abort the whole parse. The
+// unparsed text is still kept in Raw.
+func (t *Timestamp) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	*t = mustParseTimestamp(s)
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler. A zero Timestamp marshals to
+// nothing at all, so an empty <time> field doesn't round-trip into a
+// spurious element.
+func (t Timestamp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if t.Time.IsZero() {
+		return nil
+	}
+	return e.EncodeElement(t.Time.UTC().Format(time.RFC3339), start)
+}
@@ -0,0 +1,29 @@
+package gpx
+
+import "testing"
+
+// TestParseMalformedTimestampDoesNotFailDocument verifies that a single
+// malformed <time> value anywhere in a document no longer aborts the whole
+// Parse call - it falls back to a zero Time with the original text kept in
+// Raw, since this package exists to ingest messy real-world exports.
+func TestParseMalformedTimestampDoesNotFailDocument(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<gpx version="1.1" creator="test">
+<trk><trkseg>
+<trkpt lat="1.0" lon="2.0"><time>not-a-real-timestamp</time></trkpt>
+</trkseg></trk>
+</gpx>`)
+
+	g := GPX{}
+	if err := Parse(data, &g); err != nil {
+		t.Fatalf("Parse returned an error for a malformed timestamp: %v", err)
+	}
+
+	pt := g.Tracks.TrackSegments[0].TrackPoint[0]
+	if !pt.Timestamp.Time.IsZero() {
+		t.Errorf("expected zero Time for malformed timestamp, got %v", pt.Timestamp.Time)
+	}
+	if pt.Timestamp.Raw != "not-a-real-timestamp" {
+		t.Errorf("Raw = %q, want %q", pt.Timestamp.Raw, "not-a-real-timestamp")
+	}
+}